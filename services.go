@@ -1,12 +1,22 @@
 package phoenix
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime/debug"
 	"strings"
 	"sync"
 	"time"
 )
 
+// defaultStopTimeout bounds how long serviceManager.Stop will wait, in
+// total, for all services to unwind as it cancels them one by one, if
+// StopTimeout has not been set.
+const defaultStopTimeout = 5 * time.Second
+
 // Service represents a resource whose lifecycle should be managed by a Runtime.
 //
 // Typically this would be an exclusive resource such as a socket, database file,
@@ -24,6 +34,70 @@ type Service interface {
 	Stop() error
 }
 
+// ContextService may be implemented by services which prefer to receive
+// cancellation through a context.Context rather than an explicit Stop call.
+//
+// Serve runs the main loop of the Service. It is expected to block until ctx
+// is cancelled or the execution of the service is complete, and should
+// propagate ctx to any downstream goroutines it starts so they share the
+// same cancellation.
+type ContextService interface {
+	Serve(ctx context.Context) error
+}
+
+// contextServiceAdapter lets serviceManager treat every registered Service
+// as a ContextService, so Stop is only ever called indirectly through
+// context cancellation.
+type contextServiceAdapter struct {
+	Service
+}
+
+func (adapter contextServiceAdapter) Serve(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- &panicError{
+					name:  serviceName(adapter.Service),
+					value: r,
+					stack: debug.Stack(),
+				}
+			}
+		}()
+		done <- adapter.Service.Start()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if err := adapter.Service.Stop(); err != nil {
+			return err
+		}
+		return <-done
+	}
+}
+
+// asContextService returns service as a ContextService, wrapping it in an
+// adapter if it doesn't already implement the interface natively.
+func asContextService(service Service) ContextService {
+	if ctxService, ok := service.(ContextService); ok {
+		return ctxService
+	}
+	return contextServiceAdapter{service}
+}
+
+// RunService runs fn until ctx is cancelled, returning its error wrapped
+// with name so it can be attributed to the right service by callers such as
+// serviceManager. It is a convenience for services built from a single
+// function rather than a full Service/ContextService implementation.
+func RunService(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	if err := fn(ctx); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
 // Reloadable should be implemented by services which wish to respond to
 // configuration reload requests.
 type Reloadable interface {
@@ -47,20 +121,309 @@ type StopHandler interface {
 	OnStop(Container)
 }
 
+// RestartPolicy controls whether a supervised Service is restarted after
+// Start (or Serve) returns, as configured through a Policy.
+type RestartPolicy int
+
+const (
+	// Never means the service is not restarted; a failure is handled the
+	// same way as for a service registered with AddService.
+	Never RestartPolicy = iota
+
+	// OnFailure restarts the service whenever it returns a non-nil error.
+	OnFailure
+
+	// Always restarts the service whenever it returns, even with a nil
+	// error.
+	Always
+)
+
+// Policy configures suture-style supervision of a Service registered
+// through AddServiceWithPolicy: whether it is restarted, how many restarts
+// are tolerated within a sliding window, and how the restart delay grows.
+type Policy struct {
+	Restart RestartPolicy
+
+	// MaxRestarts is the number of restarts tolerated within Window before
+	// the failure is propagated to the runtime and the root context is
+	// cancelled. Zero means unlimited restarts.
+	MaxRestarts int
+
+	// Window is the sliding interval over which MaxRestarts is counted. A
+	// run lasting longer than Window resets the counter. Defaults to
+	// defaultRestartWindow when zero.
+	Window time.Duration
+
+	// InitialBackoff is the delay before the first restart attempt.
+	// Defaults to defaultInitialBackoff when zero.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the exponential growth of the restart delay.
+	// Defaults to defaultMaxBackoff when zero.
+	MaxBackoff time.Duration
+}
+
+const (
+	defaultRestartWindow  = 30 * time.Second
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultMaxBackoff     = 30 * time.Second
+)
+
+// serviceEntry pairs a registered Service with its optional supervision
+// Policy. A nil Policy behaves like Policy{Restart: Never}, i.e. the
+// behavior of a plain AddService registration.
+type serviceEntry struct {
+	Service
+	Policy *Policy
+	deps   []Service
+
+	healthMu        sync.Mutex
+	healthErr       error
+	healthCheckedAt time.Time
+
+	readyOnce sync.Once
+	ready     chan struct{}
+	done      chan struct{}
+	cancel    context.CancelFunc
+}
+
+// HealthChecker may be implemented by a Service to report its health beyond
+// simply having started. HealthCheck should return promptly, and return a
+// non-nil error if the service cannot currently do useful work.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// defaultHealthTTL is how long a HealthCheck result is cached before being
+// re-checked, if HealthTTL has not been set.
+const defaultHealthTTL = 5 * time.Second
+
+// NamedService may be implemented by a Service to provide its own name for
+// logs, health reporting, and lifecycle Events. Services which don't
+// implement it get a name derived from their concrete type instead.
+type NamedService interface {
+	Name() string
+}
+
+// serviceName returns service's name: the result of Name() if it
+// implements NamedService, otherwise a name derived from its concrete type.
+func serviceName(service Service) string {
+	if named, ok := service.(NamedService); ok {
+		return named.Name()
+	}
+
+	t := reflect.TypeOf(service)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// EventType identifies the kind of lifecycle Event emitted by a
+// serviceManager to listeners registered via OnEvent.
+type EventType int
+
+const (
+	ServiceStarting EventType = iota
+	ServiceStarted
+	ServiceStopped
+	ServiceFailed
+	ServiceRestarted
+	ReloadStarted
+	ReloadCompleted
+)
+
+// Event is published to every listener registered through Container.OnEvent
+// as the runtime's services start, stop, fail, restart, and reload. Service
+// is only set for events scoped to a single service. Err is set for a
+// ServiceFailed event, and for a ReloadCompleted event whose reload failed.
+type Event struct {
+	Type    EventType
+	Service string
+	Err     error
+}
+
 type serviceManager struct {
 	*container
-	services []Service
+	services []*serviceEntry
+
+	// StopTimeout bounds how long Stop will wait, in total, for all
+	// services to unwind as it cancels them one by one; the root context
+	// is cancelled after that wait. Defaults to defaultStopTimeout when
+	// zero.
+	StopTimeout time.Duration
+
+	// HealthTTL controls how long a cached HealthCheck result is reused
+	// before being re-checked. Defaults to defaultHealthTTL when zero.
+	HealthTTL time.Duration
+
+	// orderMu guards cancel and order, which Start populates only after
+	// every entry's own ready/done/cancel is fully set up, and which Stop
+	// reads concurrently with Start from another goroutine in the normal
+	// run-then-signal-shutdown usage pattern.
+	orderMu sync.Mutex
+	cancel  context.CancelFunc
+
+	// order holds services in the topological order computed by Start, and
+	// is walked in reverse by Stop to shut dependents down before their
+	// dependencies.
+	order []*serviceEntry
+
+	eventMu   sync.Mutex
+	listeners []func(Event)
+}
+
+// OnEvent registers fn to be called for every lifecycle Event emitted by
+// the runtime's services, giving callers a hook to plug in metrics or
+// tracing without patching Phoenix. fn may be called concurrently from
+// multiple service goroutines and must not block.
+func (manager *serviceManager) OnEvent(fn func(Event)) {
+	manager.eventMu.Lock()
+	defer manager.eventMu.Unlock()
+	manager.listeners = append(manager.listeners, fn)
+}
+
+func (manager *serviceManager) emit(event Event) {
+	manager.eventMu.Lock()
+	listeners := make([]func(Event), len(manager.listeners))
+	copy(listeners, manager.listeners)
+	manager.eventMu.Unlock()
+
+	for _, fn := range listeners {
+		fn(event)
+	}
 }
 
 func newServiceManager(container *container) *serviceManager {
 	return &serviceManager{
-		container,
-		make([]Service, 0, 1),
+		container: container,
+		services:  make([]*serviceEntry, 0, 1),
 	}
 }
 
 func (manager *serviceManager) AddService(service Service) {
-	manager.services = append(manager.services, service)
+	manager.services = append(manager.services, &serviceEntry{Service: service})
+}
+
+// AddServiceWithPolicy registers service to be supervised according to
+// policy: on failure (or success, if policy.Restart is Always) it is
+// restarted with exponential backoff instead of failing the whole runtime,
+// unless it exceeds policy.MaxRestarts within policy.Window.
+func (manager *serviceManager) AddServiceWithPolicy(service Service, policy Policy) {
+	manager.services = append(manager.services, &serviceEntry{Service: service, Policy: &policy})
+}
+
+// AddServiceAfter registers service to be started only once every service
+// in deps has started and reported itself ready (see Ready), and to be
+// stopped before them. Dependencies must themselves already be registered,
+// and dependency cycles are reported as an error from Start. Dependencies
+// are matched by interface identity, so a Service passed as a dependency
+// must be of a comparable (typically pointer) type.
+func (manager *serviceManager) AddServiceAfter(service Service, deps ...Service) {
+	manager.services = append(manager.services, &serviceEntry{Service: service, deps: deps})
+}
+
+// Ready may be implemented by a Service to signal that it has finished
+// initializing (bound a socket, opened a database, ...) once Start/Serve is
+// underway, so that services depending on it via AddServiceAfter aren't
+// started against a half-initialized dependency. Ready should return
+// promptly and return a non-nil error until initialization is complete.
+type Ready interface {
+	Ready() error
+}
+
+// defaultReadyPollInterval is how often awaitReady re-polls a Ready
+// dependency while waiting for it to report readiness.
+const defaultReadyPollInterval = 50 * time.Millisecond
+
+// awaitReady polls entry's Service (if it implements Ready) while Serve is
+// running, returning true once it reports readiness. It gives up and
+// returns false if Serve finishes this attempt before readiness is
+// reported, or ctx is cancelled, leaving the caller free to try again on
+// the next restart attempt rather than treating the give-up as readiness.
+// It must run concurrently with Serve, since Ready is only expected to
+// succeed once Start/Serve is underway.
+func (manager *serviceManager) awaitReady(ctx context.Context, entry *serviceEntry, serveDone <-chan struct{}) bool {
+	readyCheck, ok := entry.Service.(Ready)
+	if !ok {
+		return true
+	}
+
+	for {
+		if err := readyCheck.Ready(); err == nil {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-serveDone:
+			return false
+		case <-time.After(defaultReadyPollInterval):
+		}
+	}
+}
+
+// entryFor looks up the serviceEntry registered for service, used to
+// resolve the dependencies passed to AddServiceAfter. Callers must ensure
+// service is of a comparable type first (topoSort does), since comparing
+// two values of an uncomparable type panics.
+func (manager *serviceManager) entryFor(service Service) *serviceEntry {
+	for _, entry := range manager.services {
+		if entry.Service == service {
+			return entry
+		}
+	}
+	return nil
+}
+
+// topoSort returns manager.services ordered so each entry appears after
+// every dependency registered for it via AddServiceAfter. It returns an
+// error if a dependency wasn't registered, or if a dependency cycle is
+// detected.
+func (manager *serviceManager) topoSort() ([]*serviceEntry, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[*serviceEntry]int, len(manager.services))
+	order := make([]*serviceEntry, 0, len(manager.services))
+
+	var visit func(entry *serviceEntry) error
+	visit = func(entry *serviceEntry) error {
+		switch state[entry] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle detected at service %s", serviceName(entry.Service))
+		}
+
+		state[entry] = visiting
+		for _, dep := range entry.deps {
+			if !reflect.TypeOf(dep).Comparable() {
+				return fmt.Errorf("service %s depends on %T, which is not a comparable type", serviceName(entry.Service), dep)
+			}
+			depEntry := manager.entryFor(dep)
+			if depEntry == nil {
+				return fmt.Errorf("service %s depends on an unregistered service", serviceName(entry.Service))
+			}
+			if err := visit(depEntry); err != nil {
+				return err
+			}
+		}
+		state[entry] = visited
+		order = append(order, entry)
+		return nil
+	}
+
+	for _, entry := range manager.services {
+		if err := visit(entry); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
 }
 
 func (manager *serviceManager) Start() error {
@@ -68,28 +431,66 @@ func (manager *serviceManager) Start() error {
 		return errors.New("no services were registered")
 	}
 
+	order, err := manager.topoSort()
+	if err != nil {
+		faults := &multiError{}
+		faults.AddError(err)
+		return faults.AsError()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Every entry's ready/done/cancel is fully populated before order (and
+	// cancel) is published to manager under orderMu, so a concurrent Stop
+	// reading manager.order can never observe an entry that isn't yet set
+	// up to be cancelled and waited on.
+	entryCtxs := make([]context.Context, len(order))
+	for i, entry := range order {
+		entry.ready = make(chan struct{})
+		entry.done = make(chan struct{})
+		entryCtx, entryCancel := context.WithCancel(ctx)
+		entry.cancel = entryCancel
+		entryCtxs[i] = entryCtx
+	}
+
+	manager.orderMu.Lock()
+	manager.order = order
+	manager.cancel = cancel
+	manager.orderMu.Unlock()
+
+	go manager.healthLoop(ctx)
+
 	running := &sync.WaitGroup{}
 	fail := make(chan error, len(manager.services))
 
-	for _, service := range manager.services {
+	for i, entry := range order {
+		entryCtx := entryCtxs[i]
+
 		running.Add(1)
-		go func(srv Service) {
+		go func(entry *serviceEntry, entryCtx context.Context) {
 			defer running.Done()
-
-			if handler, ok := srv.(StartHandler); ok {
-				if err := handler.OnStart(manager); err != nil {
+			defer close(entry.done)
+			defer func() {
+				if r := recover(); r != nil {
+					name := serviceName(entry.Service)
+					err := &panicError{name: name, value: r, stack: debug.Stack()}
+					manager.emit(Event{Type: ServiceFailed, Service: name, Err: err})
 					fail <- err
+					cancel()
+				}
+			}()
+
+			for _, dep := range entry.deps {
+				depEntry := manager.entryFor(dep)
+				select {
+				case <-depEntry.ready:
+				case <-entryCtx.Done():
 					return
 				}
 			}
 
-			if err := srv.Start(); err != nil {
-				manager.Printf("Error while listening %s\n", err)
-				fail <- err
-			} else if handler, ok := srv.(StopHandler); ok {
-				handler.OnStop(manager)
-			}
-		}(service)
+			manager.runSupervised(entryCtx, entry, fail, cancel)
+		}(entry, entryCtx)
 	}
 
 	done := make(chan bool)
@@ -118,45 +519,328 @@ func (manager *serviceManager) Start() error {
 	return faults.AsError()
 }
 
+// runSupervised runs entry to completion, restarting it according to its
+// Policy until it settles, its restart budget is exceeded, or ctx is
+// cancelled. Failures that end supervision are sent to fail and cancel the
+// root context via cancelAll so peer services can unwind.
+func (manager *serviceManager) runSupervised(ctx context.Context, entry *serviceEntry, fail chan<- error, cancelAll context.CancelFunc) {
+	policy := entry.Policy
+	if policy == nil {
+		policy = &Policy{Restart: Never}
+	}
+
+	window := policy.Window
+	if window <= 0 {
+		window = defaultRestartWindow
+	}
+	backoff := policy.InitialBackoff
+	if backoff <= 0 {
+		backoff = defaultInitialBackoff
+	}
+	maxBackoff := policy.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+
+	name := serviceName(entry.Service)
+	var failures []time.Time
+
+	for {
+		manager.emit(Event{Type: ServiceStarting, Service: name})
+
+		if handler, ok := entry.Service.(StartHandler); ok {
+			if err := handler.OnStart(manager); err != nil {
+				manager.emit(Event{Type: ServiceFailed, Service: name, Err: err})
+				fail <- err
+				cancelAll()
+				return
+			}
+		}
+
+		manager.emit(Event{Type: ServiceStarted, Service: name})
+
+		// awaitReady is re-armed on every attempt: if this attempt's Serve
+		// exits before the dependency reports readiness (e.g. a transient
+		// bind failure that's about to be retried), entry.ready must stay
+		// open rather than releasing dependents against a service that
+		// isn't actually ready.
+		serveDone := make(chan struct{})
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					manager.emit(Event{Type: ServiceFailed, Service: name, Err: &panicError{name: name, value: r, stack: debug.Stack()}})
+				}
+			}()
+			if manager.awaitReady(ctx, entry, serveDone) {
+				entry.readyOnce.Do(func() { close(entry.ready) })
+			}
+		}()
+
+		start := time.Now()
+		err := manager.runOnce(ctx, entry.Service)
+		close(serveDone)
+		ranFor := time.Since(start)
+
+		if ctx.Err() != nil {
+			if err != nil {
+				manager.emit(Event{Type: ServiceFailed, Service: name, Err: err})
+				fail <- err
+			} else {
+				manager.emit(Event{Type: ServiceStopped, Service: name})
+				if handler, ok := entry.Service.(StopHandler); ok {
+					handler.OnStop(manager)
+				}
+			}
+			return
+		}
+
+		if err != nil {
+			manager.Printf("Error while listening %s: %s\n", name, err)
+			manager.emit(Event{Type: ServiceFailed, Service: name, Err: err})
+		} else {
+			manager.emit(Event{Type: ServiceStopped, Service: name})
+			if handler, ok := entry.Service.(StopHandler); ok {
+				handler.OnStop(manager)
+			}
+		}
+
+		if policy.Restart == Never || (policy.Restart == OnFailure && err == nil) {
+			if err != nil {
+				fail <- err
+				cancelAll()
+			}
+			return
+		}
+
+		var exceeded bool
+		failures, exceeded = recordFailure(failures, time.Now(), ranFor, window, policy.MaxRestarts)
+
+		if exceeded {
+			if err == nil {
+				err = errors.New("service exceeded restart budget")
+			}
+			fail <- fmt.Errorf("service exceeded %d restarts within %s: %w", policy.MaxRestarts, window, err)
+			cancelAll()
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = nextBackoff(backoff, maxBackoff)
+
+		manager.emit(Event{Type: ServiceRestarted, Service: name})
+	}
+}
+
+// recordFailure records a restart attempt at now against failures, the
+// sliding window of past attempts used to enforce a Policy's MaxRestarts.
+// A run lasting longer than window resets the window, since it indicates
+// the service recovered rather than crash-looping. It returns the updated
+// failures slice and whether maxRestarts has been exceeded within window.
+func recordFailure(failures []time.Time, now time.Time, ranFor, window time.Duration, maxRestarts int) ([]time.Time, bool) {
+	if ranFor > window {
+		failures = failures[:0]
+	}
+	failures = append(failures, now)
+
+	cutoff := now.Add(-window)
+	pruned := failures[:0]
+	for _, t := range failures {
+		if t.After(cutoff) {
+			pruned = append(pruned, t)
+		}
+	}
+	failures = pruned
+
+	return failures, maxRestarts > 0 && len(failures) > maxRestarts
+}
+
+// nextBackoff doubles backoff, capping the result at maxBackoff.
+func nextBackoff(backoff, maxBackoff time.Duration) time.Duration {
+	backoff *= 2
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
+
+// runOnce runs service for a single attempt, recovering a panic into an
+// error so a single misbehaving service can't take down the runtime.
+func (manager *serviceManager) runOnce(ctx context.Context, service Service) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &panicError{
+				name:  serviceName(service),
+				value: r,
+				stack: debug.Stack(),
+			}
+		}
+	}()
+	return asContextService(service).Serve(ctx)
+}
+
+// panicError records a panic recovered from a service goroutine, carrying
+// the service's name and a captured stack trace so it can be told apart
+// from an ordinary returned error in logs and in multiError.Errors.
+type panicError struct {
+	name  string
+	value interface{}
+	stack []byte
+}
+
+func (err *panicError) Error() string {
+	return fmt.Sprintf("panic in service %s: %v\n%s", err.name, err.value, err.stack)
+}
+
 func (manager *serviceManager) Reload() error {
+	manager.emit(Event{Type: ReloadStarted})
+
 	if err := manager.config.load(); err != nil {
+		manager.orderMu.Lock()
+		rootCancel := manager.cancel
+		manager.orderMu.Unlock()
+		if rootCancel != nil {
+			rootCancel()
+		}
+		manager.emit(Event{Type: ReloadCompleted, Err: err})
 		return err
 	}
 
 	failedToReload := &multiError{}
-	for _, service := range manager.services {
-		if reloadable, ok := service.(Reloadable); ok {
+	for _, entry := range manager.services {
+		if reloadable, ok := entry.Service.(Reloadable); ok {
 			failedToReload.AddError(reloadable.Reload())
 		}
 	}
 
-	return failedToReload.AsError()
+	err := failedToReload.AsError()
+	manager.emit(Event{Type: ReloadCompleted, Err: err})
+	return err
 }
 
+func (manager *serviceManager) healthTTL() time.Duration {
+	if manager.HealthTTL > 0 {
+		return manager.HealthTTL
+	}
+	return defaultHealthTTL
+}
+
+// healthLoop refreshes cached HealthCheck results once every health TTL so
+// that Check and CheckAll can usually answer an HTTP probe from the cache.
+// A Check or CheckAll that finds no result yet, or an expired one, still
+// calls HealthCheck itself and waits for it.
+func (manager *serviceManager) healthLoop(ctx context.Context) {
+	ticker := time.NewTicker(manager.healthTTL())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, entry := range manager.services {
+				if _, ok := entry.Service.(HealthChecker); ok {
+					manager.checkEntry(ctx, entry)
+				}
+			}
+		}
+	}
+}
+
+// checkEntry returns entry's cached health result, calling HealthCheck to
+// refresh it first if the cache has expired. Services which don't implement
+// HealthChecker are always considered healthy.
+func (manager *serviceManager) checkEntry(ctx context.Context, entry *serviceEntry) error {
+	checker, ok := entry.Service.(HealthChecker)
+	if !ok {
+		return nil
+	}
+
+	entry.healthMu.Lock()
+	if time.Since(entry.healthCheckedAt) < manager.healthTTL() {
+		err := entry.healthErr
+		entry.healthMu.Unlock()
+		return err
+	}
+	entry.healthMu.Unlock()
+
+	err := checker.HealthCheck(ctx)
+
+	entry.healthMu.Lock()
+	entry.healthErr = err
+	entry.healthCheckedAt = time.Now()
+	entry.healthMu.Unlock()
+
+	return err
+}
+
+// Check returns the cached health status of the named service, refreshing
+// it first if the cache has expired.
+func (manager *serviceManager) Check(name string) error {
+	for _, entry := range manager.services {
+		if serviceName(entry.Service) == name {
+			return manager.checkEntry(context.Background(), entry)
+		}
+	}
+	return fmt.Errorf("no such service: %s", name)
+}
+
+// CheckAll returns the cached health status of every registered service
+// implementing HealthChecker, keyed by its name.
+func (manager *serviceManager) CheckAll() map[string]error {
+	results := make(map[string]error)
+	for _, entry := range manager.services {
+		if _, ok := entry.Service.(HealthChecker); !ok {
+			continue
+		}
+		results[serviceName(entry.Service)] = manager.checkEntry(context.Background(), entry)
+	}
+	return results
+}
+
+// Stop cancels services in reverse topological order, waiting for each one
+// to unwind before cancelling its dependencies, so a service never stops
+// before the services depending on it do.
 func (manager *serviceManager) Stop() error {
+	timeout := manager.StopTimeout
+	if timeout <= 0 {
+		timeout = defaultStopTimeout
+	}
+	deadline := time.Now().Add(timeout)
+
+	manager.orderMu.Lock()
+	order := manager.order
+	rootCancel := manager.cancel
+	manager.orderMu.Unlock()
+
 	faults := &multiError{}
-	stopping := sync.WaitGroup{}
-	for i := len(manager.services) -1; i >=0; i-- {
-		service := manager.services[i]
-		fault := make(chan error, 1)
-		stopping.Add(1)
-		go func() {
-			fault <- service.Stop()
-		}()
 
-		go func() {
-			defer stopping.Done()
-			var err error
-			select {
-			case err = <- fault:
-			case <- time.After(5 * time.Second):
-				err = errors.New("timed out waiting for service to stop")
-			}
-			faults.AddError(err)
-		}()
+	for i := len(order) - 1; i >= 0; i-- {
+		entry := order[i]
+		if entry.cancel != nil {
+			entry.cancel()
+		}
+
+		remaining := time.Until(deadline)
+		if remaining < 0 {
+			remaining = 0
+		}
+
+		select {
+		case <-entry.done:
+		case <-time.After(remaining):
+			faults.AddError(fmt.Errorf("timed out waiting for service %s to stop", serviceName(entry.Service)))
+		}
+	}
+
+	if rootCancel != nil {
+		rootCancel()
 	}
 
-	stopping.Wait()
 	return faults.AsError()
 }
 
@@ -193,3 +877,58 @@ func (stop *multiError) AsError() error {
 	}
 	return stop
 }
+
+// Errors returns the individual errors collected so far, in the order they
+// were added. Callers can use errors.As against the result to pick out
+// panics (reported as *panicError) from ordinary service errors.
+func (stop *multiError) Errors() []error {
+	stop.Lock()
+	defer stop.Unlock()
+
+	errs := make([]error, len(stop.errors))
+	copy(errs, stop.errors)
+	return errs
+}
+
+// HealthCheckable is implemented by a Container whose services can be
+// probed for health, as surfaced by serviceManager's Check and CheckAll.
+type HealthCheckable interface {
+	Check(name string) error
+	CheckAll() map[string]error
+}
+
+// HealthHTTPHandler returns an http.Handler exposing /healthz, a liveness
+// probe that succeeds as long as the process is up, and /readyz, a
+// readiness probe that aggregates runtime.CheckAll and reports 503 if any
+// service is unhealthy.
+func HealthHTTPHandler(runtime HealthCheckable) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		results := runtime.CheckAll()
+
+		status := http.StatusOK
+		for _, err := range results {
+			if err != nil {
+				status = http.StatusServiceUnavailable
+				break
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(status)
+		for name, err := range results {
+			if err != nil {
+				fmt.Fprintf(w, "%s: failed: %s\n", name, err)
+			} else {
+				fmt.Fprintf(w, "%s: ok\n", name)
+			}
+		}
+	})
+
+	return mux
+}
@@ -0,0 +1,291 @@
+package phoenix
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubService is a minimal Service used to exercise topoSort without
+// needing a real container.
+type stubService struct{ name string }
+
+func (s *stubService) Start() error { return nil }
+func (s *stubService) Stop() error  { return nil }
+func (s *stubService) Name() string { return s.name }
+
+// uncomparableService is a Service implemented on a value type holding a
+// slice, so it is not comparable and can't be used as an AddServiceAfter
+// dependency.
+type uncomparableService struct{ items []int }
+
+func (uncomparableService) Start() error { return nil }
+func (uncomparableService) Stop() error  { return nil }
+
+// blockingService blocks in Start until Stop unblocks it, like a real
+// Service whose main loop runs until told to shut down.
+type blockingService struct {
+	stop chan struct{}
+}
+
+func newBlockingService() *blockingService {
+	return &blockingService{stop: make(chan struct{})}
+}
+
+func (s *blockingService) Start() error {
+	<-s.stop
+	return nil
+}
+
+func (s *blockingService) Stop() error {
+	close(s.stop)
+	return nil
+}
+
+// TestServiceManagerStartStopLifecycle exercises Start and Stop with real
+// goroutines in the library's normal usage pattern: Start is run in the
+// background and Stop is called concurrently from another goroutine once
+// the caller decides to shut down.
+func TestServiceManagerStartStopLifecycle(t *testing.T) {
+	manager := &serviceManager{container: &container{}}
+	manager.AddService(newBlockingService())
+
+	startErr := make(chan error, 1)
+	go func() {
+		startErr <- manager.Start()
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+
+	if err := manager.Stop(); err != nil {
+		t.Fatalf("Stop() = %v, want nil", err)
+	}
+
+	select {
+	case err := <-startErr:
+		if err != nil {
+			t.Fatalf("Start() = %v, want nil", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Start() did not return after Stop()")
+	}
+}
+
+// panickingService panics in Start, like a service that hits a nil
+// pointer or index-out-of-range bug while binding a resource.
+type panickingService struct{}
+
+func (panickingService) Start() error { panic("boom") }
+func (panickingService) Stop() error  { return nil }
+
+// TestServiceManagerStartRecoversPanic is a regression test for a
+// panicking Service.Start crashing the whole process instead of being
+// reported as a *panicError: it drives Start for real, rather than
+// calling runOnce directly, so it also covers contextServiceAdapter's
+// inner goroutine, the path every plain (non-ContextService) Service
+// goes through.
+func TestServiceManagerStartRecoversPanic(t *testing.T) {
+	manager := &serviceManager{container: &container{}}
+	manager.AddService(panickingService{})
+
+	err := manager.Start()
+	if err == nil {
+		t.Fatal("Start() = nil, want an error reporting the panic")
+	}
+
+	faults, ok := err.(*multiError)
+	if !ok {
+		t.Fatalf("Start() error = %v (%T), want *multiError", err, err)
+	}
+
+	errs := faults.Errors()
+	if len(errs) != 1 {
+		t.Fatalf("Start() reported %d errors, want 1: %v", len(errs), errs)
+	}
+
+	var asPanic *panicError
+	if !errors.As(errs[0], &asPanic) {
+		t.Fatalf("Start() error %v does not unwrap to *panicError", errs[0])
+	}
+}
+
+func TestNextBackoff(t *testing.T) {
+	cases := []struct {
+		backoff, maxBackoff, want time.Duration
+	}{
+		{time.Second, 30 * time.Second, 2 * time.Second},
+		{20 * time.Second, 30 * time.Second, 30 * time.Second},
+		{30 * time.Second, 30 * time.Second, 30 * time.Second},
+	}
+
+	for _, c := range cases {
+		if got := nextBackoff(c.backoff, c.maxBackoff); got != c.want {
+			t.Errorf("nextBackoff(%s, %s) = %s, want %s", c.backoff, c.maxBackoff, got, c.want)
+		}
+	}
+}
+
+func TestRecordFailureWithinBudget(t *testing.T) {
+	now := time.Unix(1000, 0)
+	window := 30 * time.Second
+
+	var failures []time.Time
+	var exceeded bool
+
+	failures, exceeded = recordFailure(failures, now, time.Second, window, 2)
+	if exceeded {
+		t.Fatalf("exceeded budget after first failure")
+	}
+
+	failures, exceeded = recordFailure(failures, now.Add(time.Second), time.Second, window, 2)
+	if exceeded {
+		t.Fatalf("exceeded budget after second failure")
+	}
+
+	failures, exceeded = recordFailure(failures, now.Add(2*time.Second), time.Second, window, 2)
+	if !exceeded {
+		t.Fatalf("expected budget to be exceeded after third failure within window")
+	}
+	if len(failures) != 3 {
+		t.Fatalf("expected 3 recorded failures, got %d", len(failures))
+	}
+}
+
+func TestRecordFailurePrunesOutsideWindow(t *testing.T) {
+	now := time.Unix(1000, 0)
+	window := 10 * time.Second
+
+	failures, _ := recordFailure(nil, now, time.Second, window, 1)
+	failures, exceeded := recordFailure(failures, now.Add(time.Hour), time.Second, window, 1)
+
+	if exceeded {
+		t.Fatalf("failure outside window should not count toward the budget")
+	}
+	if len(failures) != 1 {
+		t.Fatalf("expected stale failure to be pruned, got %d entries", len(failures))
+	}
+}
+
+func TestRecordFailureResetsAfterLongRun(t *testing.T) {
+	now := time.Unix(1000, 0)
+	window := 10 * time.Second
+
+	failures, _ := recordFailure(nil, now, time.Second, window, 1)
+	failures, exceeded := recordFailure(failures, now.Add(time.Second), window+time.Second, window, 1)
+
+	if exceeded {
+		t.Fatalf("a run lasting longer than window should reset the failure count")
+	}
+	if len(failures) != 1 {
+		t.Fatalf("expected failure count to reset to 1, got %d", len(failures))
+	}
+}
+
+func TestMultiErrorAsErrorNilWhenEmpty(t *testing.T) {
+	faults := &multiError{}
+	if err := faults.AsError(); err != nil {
+		t.Fatalf("AsError() on an empty multiError = %v, want nil", err)
+	}
+}
+
+func TestMultiErrorErrorsPreservesOrderAndType(t *testing.T) {
+	first := errors.New("boom")
+	second := &panicError{name: "worker", value: "oops", stack: []byte("stack")}
+
+	faults := &multiError{}
+	faults.AddError(nil) // nil errors are dropped, not recorded
+	faults.AddError(first)
+	faults.AddError(second)
+
+	errs := faults.Errors()
+	if len(errs) != 2 {
+		t.Fatalf("Errors() returned %d errors, want 2", len(errs))
+	}
+	if errs[0] != first {
+		t.Errorf("Errors()[0] = %v, want %v", errs[0], first)
+	}
+
+	var asPanic *panicError
+	if !errors.As(errs[1], &asPanic) {
+		t.Fatalf("Errors()[1] did not unwrap to *panicError via errors.As")
+	}
+	if asPanic != second {
+		t.Errorf("errors.As resolved to %v, want %v", asPanic, second)
+	}
+
+	if err := faults.AsError(); err == nil {
+		t.Fatalf("AsError() on a non-empty multiError = nil, want non-nil")
+	}
+}
+
+func indexOf(order []*serviceEntry, service Service) int {
+	for i, entry := range order {
+		if entry.Service == service {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestTopoSortOrdersDependenciesFirst(t *testing.T) {
+	a := &stubService{name: "a"}
+	b := &stubService{name: "b"}
+	c := &stubService{name: "c"}
+
+	eA := &serviceEntry{Service: a}
+	eB := &serviceEntry{Service: b, deps: []Service{a}}
+	eC := &serviceEntry{Service: c, deps: []Service{b}}
+
+	manager := &serviceManager{services: []*serviceEntry{eC, eB, eA}}
+
+	order, err := manager.topoSort()
+	if err != nil {
+		t.Fatalf("topoSort() returned unexpected error: %v", err)
+	}
+
+	if ia, ib, ic := indexOf(order, a), indexOf(order, b), indexOf(order, c); !(ia < ib && ib < ic) {
+		t.Fatalf("expected order a, b, c; got indices a=%d b=%d c=%d", ia, ib, ic)
+	}
+}
+
+func TestTopoSortDetectsCycle(t *testing.T) {
+	a := &stubService{name: "a"}
+	b := &stubService{name: "b"}
+
+	eA := &serviceEntry{Service: a, deps: []Service{b}}
+	eB := &serviceEntry{Service: b, deps: []Service{a}}
+
+	manager := &serviceManager{services: []*serviceEntry{eA, eB}}
+
+	_, err := manager.topoSort()
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("topoSort() error = %v, want a dependency cycle error", err)
+	}
+}
+
+func TestTopoSortDetectsUnregisteredDependency(t *testing.T) {
+	a := &stubService{name: "a"}
+	missing := &stubService{name: "missing"}
+
+	eA := &serviceEntry{Service: a, deps: []Service{missing}}
+
+	manager := &serviceManager{services: []*serviceEntry{eA}}
+
+	_, err := manager.topoSort()
+	if err == nil || !strings.Contains(err.Error(), "unregistered") {
+		t.Fatalf("topoSort() error = %v, want an unregistered dependency error", err)
+	}
+}
+
+func TestTopoSortRejectsUncomparableDependency(t *testing.T) {
+	a := &stubService{name: "a"}
+	eA := &serviceEntry{Service: a, deps: []Service{uncomparableService{items: []int{1, 2, 3}}}}
+
+	manager := &serviceManager{services: []*serviceEntry{eA}}
+
+	_, err := manager.topoSort()
+	if err == nil || !strings.Contains(err.Error(), "not a comparable type") {
+		t.Fatalf("topoSort() error = %v, want a not-comparable-type error", err)
+	}
+}